@@ -0,0 +1,284 @@
+// Package b2 is the Backblaze B2 bucketfile.Backend, talking directly to
+// the B2 native API (https://www.backblaze.com/apidocs/introduction-to-the-b2-native-api).
+package b2
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xyproto/bucketfile/backend"
+)
+
+const apiBaseURL = "https://api.backblazeb2.com/b2api/v3"
+
+// Backend is a bucketfile.Backend backed by a B2 bucket.
+type Backend struct {
+	http      *http.Client
+	apiURL    string
+	authToken string
+	bucketID  string
+	bucket    string
+}
+
+// New returns a Backend for the given B2 bucket name, authenticating with
+// the key ID and application key from the B2_ACCOUNT_ID and
+// B2_APPLICATION_KEY environment variables.
+func New(ctx context.Context, bucket string) (*Backend, error) {
+	keyID := os.Getenv("B2_ACCOUNT_ID")
+	appKey := os.Getenv("B2_APPLICATION_KEY")
+	if keyID == "" || appKey == "" {
+		return nil, fmt.Errorf("b2.New: B2_ACCOUNT_ID and B2_APPLICATION_KEY must be set")
+	}
+
+	b := &Backend{http: http.DefaultClient, bucket: bucket}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBaseURL+"/b2_authorize_account", nil)
+	if err != nil {
+		return nil, fmt.Errorf("b2_authorize_account: %v", err)
+	}
+	req.SetBasicAuth(keyID, appKey)
+
+	var auth struct {
+		AuthorizationToken string `json:"authorizationToken"`
+		APIInfo            struct {
+			StorageAPI struct {
+				APIURL string `json:"apiUrl"`
+			} `json:"storageApi"`
+		} `json:"apiInfo"`
+	}
+	if err := b.do(req, &auth); err != nil {
+		return nil, fmt.Errorf("b2_authorize_account: %v", err)
+	}
+	b.authToken = auth.AuthorizationToken
+	b.apiURL = auth.APIInfo.StorageAPI.APIURL
+
+	var listBuckets struct {
+		Buckets []struct {
+			BucketID   string `json:"bucketId"`
+			BucketName string `json:"bucketName"`
+		} `json:"buckets"`
+	}
+	req, err = b.newRequest(ctx, http.MethodGet, "/b2_list_buckets?bucketName="+bucket, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.do(req, &listBuckets); err != nil {
+		return nil, fmt.Errorf("b2_list_buckets: %v", err)
+	}
+	for _, bk := range listBuckets.Buckets {
+		if bk.BucketName == bucket {
+			b.bucketID = bk.BucketID
+		}
+	}
+	if b.bucketID == "" {
+		return nil, fmt.Errorf("b2.New: bucket %q not found", bucket)
+	}
+
+	return b, nil
+}
+
+// b2PathEscape percent-encodes name for use in a B2 URL path or the
+// X-Bz-File-Name header, both of which B2 requires to be RFC 3986
+// encoded, while leaving "/" unescaped since B2 treats it as a literal
+// path separator within a file name.
+func b2PathEscape(name string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func (b *Backend) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, b.apiURL+"/b2api/v3"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", b.authToken)
+	return req, nil
+}
+
+func (b *Backend) do(req *http.Request, out interface{}) error {
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s: %s", req.URL, resp.Status, data)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Upload reads r and stores it as object.
+func (b *Backend) Upload(ctx context.Context, r io.Reader, object string) error {
+	var getURL struct {
+		UploadURL string `json:"uploadUrl"`
+		AuthToken string `json:"authorizationToken"`
+	}
+	req, err := b.newRequest(ctx, http.MethodPost, "/b2_get_upload_url?bucketId="+b.bucketID, nil)
+	if err != nil {
+		return err
+	}
+	if err := b.do(req, &getURL); err != nil {
+		return fmt.Errorf("b2_get_upload_url: %v", err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("io.ReadAll: %v", err)
+	}
+
+	uploadReq, err := http.NewRequestWithContext(ctx, http.MethodPost, getURL.UploadURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("b2_upload_file: %v", err)
+	}
+	sha1sum := sha1.Sum(data)
+
+	uploadReq.Header.Set("Authorization", getURL.AuthToken)
+	uploadReq.Header.Set("X-Bz-File-Name", b2PathEscape(object))
+	uploadReq.Header.Set("Content-Type", "b2/x-auto")
+	uploadReq.Header.Set("X-Bz-Content-Sha1", hex.EncodeToString(sha1sum[:]))
+	uploadReq.ContentLength = int64(len(data))
+
+	if err := b.do(uploadReq, nil); err != nil {
+		return fmt.Errorf("b2_upload_file: %v", err)
+	}
+	return nil
+}
+
+// Get returns a reader over object's data. Callers must Close it.
+func (b *Backend) Get(ctx context.Context, object string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.apiURL+"/file/"+url.PathEscape(b.bucket)+"/"+b2PathEscape(object), nil)
+	if err != nil {
+		return nil, fmt.Errorf("b2_download_file_by_name: %v", err)
+	}
+	req.Header.Set("Authorization", b.authToken)
+
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("b2_download_file_by_name: %v", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("b2_download_file_by_name(%q): %s: %s", object, resp.Status, data)
+	}
+	return resp.Body, nil
+}
+
+// List returns the names of every object whose name starts with prefix.
+func (b *Backend) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	startFileName := ""
+
+	for {
+		var page struct {
+			Files []struct {
+				FileName string `json:"fileName"`
+			} `json:"files"`
+			NextFileName string `json:"nextFileName"`
+		}
+
+		body, _ := json.Marshal(map[string]string{
+			"bucketId":      b.bucketID,
+			"prefix":        prefix,
+			"startFileName": startFileName,
+		})
+		req, err := b.newRequest(ctx, http.MethodPost, "/b2_list_file_names", bytes.NewReader(body))
+		if err != nil {
+			return names, err
+		}
+		if err := b.do(req, &page); err != nil {
+			return names, fmt.Errorf("b2_list_file_names: %v", err)
+		}
+
+		for _, f := range page.Files {
+			names = append(names, f.FileName)
+		}
+		if page.NextFileName == "" {
+			break
+		}
+		startFileName = page.NextFileName
+	}
+
+	return names, nil
+}
+
+// headFile issues a HEAD against the download-by-name endpoint, which B2
+// answers with the same x-bz-* headers as a GET.
+func (b *Backend) headFile(ctx context.Context, object string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, b.apiURL+"/file/"+url.PathEscape(b.bucket)+"/"+b2PathEscape(object), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", b.authToken)
+
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", object, resp.Status)
+	}
+	return resp, nil
+}
+
+// Delete removes object.
+func (b *Backend) Delete(ctx context.Context, object string) error {
+	resp, err := b.headFile(ctx, object)
+	if err != nil {
+		return fmt.Errorf("b2_head_file_by_name(%q): %v", object, err)
+	}
+	resp.Body.Close()
+	fileID := resp.Header.Get("X-Bz-File-Id")
+
+	body, _ := json.Marshal(map[string]string{
+		"fileName": object,
+		"fileId":   fileID,
+	})
+	req, err := b.newRequest(ctx, http.MethodPost, "/b2_delete_file_version", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if err := b.do(req, nil); err != nil {
+		return fmt.Errorf("b2_delete_file_version(%q): %v", object, err)
+	}
+	return nil
+}
+
+// Stat returns metadata about object without reading its data.
+func (b *Backend) Stat(ctx context.Context, object string) (backend.ObjectInfo, error) {
+	resp, err := b.headFile(ctx, object)
+	if err != nil {
+		return backend.ObjectInfo{}, fmt.Errorf("b2_head_file_by_name(%q): %v", object, err)
+	}
+	defer resp.Body.Close()
+
+	info := backend.ObjectInfo{Name: object}
+	if n, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = n
+	}
+	if ms, err := strconv.ParseInt(resp.Header.Get("X-Bz-Upload-Timestamp"), 10, 64); err == nil {
+		info.ModTime = time.UnixMilli(ms)
+	}
+
+	return info, nil
+}
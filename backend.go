@@ -0,0 +1,55 @@
+package bucketfile
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/xyproto/bucketfile/b2"
+	"github.com/xyproto/bucketfile/backend"
+	"github.com/xyproto/bucketfile/file"
+	"github.com/xyproto/bucketfile/gcs"
+	"github.com/xyproto/bucketfile/s3"
+)
+
+// ObjectInfo describes a single object, independent of which vendor is
+// behind the Backend.
+type ObjectInfo = backend.ObjectInfo
+
+// Backend is a storage vendor bound to a single bucket (or, for the file
+// backend, a single root directory). Object names are always relative to
+// that bucket/directory. See the gcs, s3, b2 and file subpackages for the
+// concrete implementations, or use Open to pick one from a URL.
+type Backend = backend.Backend
+
+// Open parses rawURL and returns the Backend it identifies. The scheme
+// selects the vendor and the rest of the URL selects the bucket (or, for
+// file:// URLs, the root directory):
+//
+//	gs://bucket      Google Cloud Storage
+//	s3://bucket      Amazon S3
+//	b2://bucket      Backblaze B2
+//	file:///tmp/dir  local filesystem, useful for tests and dev
+//
+// Any object/path segment after the bucket is ignored; Backend's methods
+// take object names relative to the bucket themselves.
+func Open(ctx context.Context, rawURL string) (Backend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse(%q): %v", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "gs":
+		return gcs.New(ctx, u.Host)
+	case "s3":
+		return s3.New(ctx, u.Host)
+	case "b2":
+		return b2.New(ctx, u.Host)
+	case "file":
+		return file.New(strings.TrimPrefix(rawURL, "file://"))
+	default:
+		return nil, fmt.Errorf("Open(%q): unsupported scheme %q", rawURL, u.Scheme)
+	}
+}
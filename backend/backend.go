@@ -0,0 +1,36 @@
+// Package backend declares the vendor-agnostic storage interface that the
+// gcs, s3, b2 and file packages implement, and that bucketfile.Open
+// returns. It has no dependency on those packages, or on bucketfile
+// itself, so they can all depend on it without an import cycle.
+package backend
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ObjectInfo describes a single object, independent of which vendor is
+// behind the Backend.
+type ObjectInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Backend is a storage vendor bound to a single bucket (or, for the file
+// backend, a single root directory). Object names are always relative to
+// that bucket/directory.
+type Backend interface {
+	// Upload reads r and stores it as object.
+	Upload(ctx context.Context, r io.Reader, object string) error
+	// Get returns a reader over object's data. Callers must Close it.
+	Get(ctx context.Context, object string) (io.ReadCloser, error)
+	// List returns the names of every object whose name starts with
+	// prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete removes object.
+	Delete(ctx context.Context, object string) error
+	// Stat returns metadata about object without reading its data.
+	Stat(ctx context.Context, object string) (ObjectInfo, error)
+}
@@ -0,0 +1,43 @@
+package bucketfile
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestOpenFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	b, err := Open(ctx, "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	want := []byte("hello from Open")
+	if err := b.Upload(ctx, bytes.NewReader(want), "greeting.txt"); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	rc, err := b.Get(ctx, "greeting.txt")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Get: got %q, want %q", got, want)
+	}
+}
+
+func TestOpenUnsupportedScheme(t *testing.T) {
+	if _, err := Open(context.Background(), "ftp://bucket"); err == nil {
+		t.Fatalf("Open(ftp://...): expected error, got nil")
+	}
+}
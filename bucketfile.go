@@ -10,53 +10,53 @@ import (
 	"io"
 	"io/ioutil"
 	"time"
-
-	"cloud.google.com/go/storage"
-	"google.golang.org/api/iterator"
 )
 
+// timeoutUpload is the timeout used by the top-level Upload function and
+// Client.Upload.
+const timeoutUpload = 50 * time.Second
+
+// timeoutList is the timeout used by the top-level List function.
+const timeoutList = 10 * time.Second
+
 // Upload takes an io.Reader, bucket name and object name
 // and uploads the file to the bucket. It has a 50 second timeout.
+//
+// Upload creates a new Client for this single call. Callers making
+// repeated calls should construct a Client with NewClient instead.
 func Upload(file io.Reader, bucket, object string) error {
 	ctx := context.Background()
-	client, err := storage.NewClient(ctx)
+	client, err := NewClient(ctx)
 	if err != nil {
-		return fmt.Errorf("storage.NewClient: %v", err)
+		return err
 	}
 	defer client.Close()
 
-	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
-	defer cancel()
-
-	// Upload an object with storage.Writer.
-	wc := client.Bucket(bucket).Object(object).NewWriter(ctx)
-	if _, err = io.Copy(wc, file); err != nil {
-		return fmt.Errorf("io.Copy: %v", err)
-	}
-	if err := wc.Close(); err != nil {
-		return fmt.Errorf("Writer.Close: %v", err)
-	}
-
-	return nil
+	return client.Upload(file, bucket, object)
 }
 
 // Get takes a bucket name and an object name and
 // returns the file data as a slice of bytes.
 // It has a 50 second timeout.
+//
+// Get loads the entire object into memory, which breaks down for objects
+// larger than available RAM; use Client.GetReader for a streaming,
+// range-capable alternative. Get creates a new Client for this single
+// call. Callers making repeated calls should construct a Client with
+// NewClient instead.
 func Get(bucket, object string) ([]byte, error) {
-	ctx := context.Background()
-	client, err := storage.NewClient(ctx)
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutUpload)
+	defer cancel()
+
+	client, err := NewClient(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("storage.NewClient: %v", err)
+		return nil, err
 	}
 	defer client.Close()
 
-	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
-	defer cancel()
-
-	rc, err := client.Bucket(bucket).Object(object).NewReader(ctx)
+	rc, _, err := client.GetReader(ctx, bucket, object, 0, -1)
 	if err != nil {
-		return nil, fmt.Errorf("Object(%q).NewReader: %v", object, err)
+		return nil, err
 	}
 	defer rc.Close()
 
@@ -68,32 +68,31 @@ func Get(bucket, object string) ([]byte, error) {
 	return data, nil
 }
 
-// List lists objects within the specified bucket
-// It has a 10 second timeout.
+// List lists objects within the specified bucket. It has a 10 second
+// timeout.
+//
+// List accumulates every object name into a slice, which breaks down for
+// buckets with millions of objects; use Client.List for a single page or
+// Client.ListAll to stream lazily. List creates a new Client for this
+// single call. Callers making repeated calls should construct a Client
+// with NewClient instead.
 func List(bucket string) ([]string, error) {
-	var fileNames []string
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutList)
+	defer cancel()
 
-	ctx := context.Background()
-	client, err := storage.NewClient(ctx)
+	client, err := NewClient(ctx)
 	if err != nil {
-		return fileNames, fmt.Errorf("storage.NewClient: %v", err)
+		return nil, err
 	}
 	defer client.Close()
 
-	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
-	defer cancel()
-
-	it := client.Bucket(bucket).Objects(ctx, nil)
-	for {
-		attrs, err := it.Next()
-		if err == iterator.Done {
-			break
-		}
+	var names []string
+	for attrs, err := range client.ListAll(ctx, bucket, ListOptions{}) {
 		if err != nil {
-			return fileNames, fmt.Errorf("Bucket(%q).Objects: %v", bucket, err)
+			return names, err
 		}
-		fileNames = append(fileNames, attrs.Name)
+		names = append(names, attrs.Name)
 	}
 
-	return fileNames, nil
+	return names, nil
 }
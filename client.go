@@ -0,0 +1,171 @@
+package bucketfile
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// Client wraps a single *storage.Client so that callers don't need to pay
+// the cost of dialing a new client on every Upload/Get/List call. It is
+// safe for concurrent use, as the underlying storage.Client is.
+type Client struct {
+	sc *storage.Client
+}
+
+// NewClient creates a Client, passing opts through to storage.NewClient.
+// This is the place to supply credentials, a custom HTTP client, or point
+// at a local fake GCS server for tests.
+func NewClient(ctx context.Context, opts ...option.ClientOption) (*Client, error) {
+	sc, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("storage.NewClient: %v", err)
+	}
+	return &Client{sc: sc}, nil
+}
+
+// Close closes the underlying storage.Client.
+func (c *Client) Close() error {
+	return c.sc.Close()
+}
+
+// Upload takes an io.Reader, bucket name and object name and uploads the
+// file to the bucket. It has a 50 second timeout.
+func (c *Client) Upload(file io.Reader, bucket, object string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutUpload)
+	defer cancel()
+
+	wc := c.sc.Bucket(bucket).Object(object).NewWriter(ctx)
+	if _, err := io.Copy(wc, file); err != nil {
+		return fmt.Errorf("io.Copy: %v", err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("Writer.Close: %v", err)
+	}
+
+	return nil
+}
+
+// GetOption configures a Client.GetReader call.
+type GetOption func(*getConfig)
+
+type getConfig struct {
+	stallTimeout bool
+}
+
+// ReadWithStallTimeout overrides StallTimeoutEnabled for a single
+// GetReader call.
+func ReadWithStallTimeout(enabled bool) GetOption {
+	return func(c *getConfig) {
+		c.stallTimeout = enabled
+	}
+}
+
+// GetReader takes a bucket name and an object name and returns a reader
+// over the object's data, along with its attributes. If length is
+// negative, the object is read from offset until the end. Callers must
+// Close the returned io.ReadCloser.
+//
+// Unlike Get, GetReader streams the object rather than buffering it in
+// memory, and supports range reads, which makes it usable for objects
+// larger than available RAM and for integration with http.ServeContent.
+//
+// Stall-timeout retries are off by default; pass ReadWithStallTimeout(true),
+// or set the package-level StallTimeoutEnabled, to make GetReader
+// proactively cancel and retry the GET if the server hasn't returned the
+// first byte within that bucket's current stall timeout, rather than
+// waiting for ctx to expire. After maxStallRetries such retries, it falls
+// back to a single final attempt bound only by ctx.
+func (c *Client) GetReader(ctx context.Context, bucket, object string, offset, length int64, opts ...GetOption) (io.ReadCloser, *storage.ObjectAttrs, error) {
+	cfg := getConfig{stallTimeout: StallTimeoutEnabled}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	obj := c.sc.Bucket(bucket).Object(object)
+
+	if !cfg.stallTimeout {
+		return newRangeReader(ctx, obj, object, offset, length)
+	}
+
+	for attempt := 0; attempt <= maxStallRetries; attempt++ {
+		attemptCtx, cancel := context.WithCancel(ctx)
+
+		start := time.Now()
+		rc, err := obj.NewRangeReader(attemptCtx, offset, length)
+		if err != nil {
+			cancel()
+			return nil, nil, fmt.Errorf("Object(%q).NewRangeReader: %v", object, err)
+		}
+
+		br := bufio.NewReader(rc)
+		timeout := stallTimeoutFor(bucket)
+
+		peeked := make(chan error, 1)
+		go func() { _, err := br.Peek(1); peeked <- err }()
+
+		select {
+		case err := <-peeked:
+			if err != nil && err != io.EOF {
+				cancel()
+				rc.Close()
+				return nil, nil, fmt.Errorf("reading first byte: %v", err)
+			}
+			stallWindowFor(bucket).add(time.Since(start), false)
+
+			attrs, err := obj.Attrs(ctx)
+			if err != nil {
+				cancel()
+				rc.Close()
+				return nil, nil, fmt.Errorf("Object(%q).Attrs: %v", object, err)
+			}
+			return &stallReader{r: br, rc: rc, cancel: cancel}, attrs, nil
+
+		case <-time.After(timeout):
+			cancel()
+			rc.Close()
+			stallWindowFor(bucket).add(timeout, true)
+		}
+	}
+
+	return newRangeReader(ctx, obj, object, offset, length)
+}
+
+// newRangeReader opens obj with a plain, non-stall-timeout-aware range
+// reader bound by ctx.
+func newRangeReader(ctx context.Context, obj *storage.ObjectHandle, object string, offset, length int64) (io.ReadCloser, *storage.ObjectAttrs, error) {
+	rc, err := obj.NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Object(%q).NewRangeReader: %v", object, err)
+	}
+
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		rc.Close()
+		return nil, nil, fmt.Errorf("Object(%q).Attrs: %v", object, err)
+	}
+
+	return rc, attrs, nil
+}
+
+// stallReader wraps a buffered range reader whose first byte has already
+// been peeked, cancelling its attempt-scoped context on Close.
+type stallReader struct {
+	r      *bufio.Reader
+	rc     io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+func (s *stallReader) Close() error {
+	defer s.cancel()
+	return s.rc.Close()
+}
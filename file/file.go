@@ -0,0 +1,126 @@
+// Package file is the local filesystem bucketfile.Backend, useful for
+// tests and local development without touching a network.
+package file
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xyproto/bucketfile/backend"
+)
+
+// Backend is a bucketfile.Backend backed by a directory on the local
+// filesystem. Object names map directly to paths under root.
+type Backend struct {
+	root string
+}
+
+// New returns a Backend rooted at dir, creating it if it doesn't exist.
+func New(dir string) (*Backend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll(%q): %v", dir, err)
+	}
+	return &Backend{root: filepath.Clean(dir)}, nil
+}
+
+// path resolves object to a path under root, rejecting any object name
+// that would escape it.
+func (b *Backend) path(object string) (string, error) {
+	p := filepath.Join(b.root, filepath.FromSlash(object))
+	if p != b.root && !strings.HasPrefix(p, b.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("object %q escapes backend root", object)
+	}
+	return p, nil
+}
+
+// Upload reads r and stores it as object.
+func (b *Backend) Upload(ctx context.Context, r io.Reader, object string) error {
+	p, err := b.path(object)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %v", err)
+	}
+
+	f, err := os.Create(p)
+	if err != nil {
+		return fmt.Errorf("os.Create(%q): %v", p, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("io.Copy: %v", err)
+	}
+	return nil
+}
+
+// Get returns a reader over object's data. Callers must Close it.
+func (b *Backend) Get(ctx context.Context, object string) (io.ReadCloser, error) {
+	p, err := b.path(object)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open(%q): %v", p, err)
+	}
+	return f, nil
+}
+
+// List returns the names of every object whose name starts with prefix.
+func (b *Backend) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+
+	err := filepath.WalkDir(b.root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return names, fmt.Errorf("filepath.WalkDir(%q): %v", b.root, err)
+	}
+
+	return names, nil
+}
+
+// Delete removes object.
+func (b *Backend) Delete(ctx context.Context, object string) error {
+	p, err := b.path(object)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil {
+		return fmt.Errorf("os.Remove(%q): %v", p, err)
+	}
+	return nil
+}
+
+// Stat returns metadata about object without reading its data.
+func (b *Backend) Stat(ctx context.Context, object string) (backend.ObjectInfo, error) {
+	p, err := b.path(object)
+	if err != nil {
+		return backend.ObjectInfo{}, err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return backend.ObjectInfo{}, fmt.Errorf("os.Stat(%q): %v", p, err)
+	}
+	return backend.ObjectInfo{Name: object, Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
@@ -0,0 +1,89 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestBackendUploadGetDeleteStat(t *testing.T) {
+	b, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	want := []byte("hello, bucketfile")
+	if err := b.Upload(ctx, bytes.NewReader(want), "dir/greeting.txt"); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	rc, err := b.Get(ctx, "dir/greeting.txt")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Get: got %q, want %q", got, want)
+	}
+
+	info, err := b.Stat(ctx, "dir/greeting.txt")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Name != "dir/greeting.txt" || info.Size != int64(len(want)) {
+		t.Fatalf("Stat: got %+v", info)
+	}
+
+	if err := b.Delete(ctx, "dir/greeting.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := b.Get(ctx, "dir/greeting.txt"); err == nil {
+		t.Fatalf("Get after Delete: expected error, got nil")
+	}
+}
+
+func TestBackendList(t *testing.T) {
+	b, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, name := range []string{"a/1.txt", "a/2.txt", "b/1.txt"} {
+		if err := b.Upload(ctx, bytes.NewReader([]byte("x")), name); err != nil {
+			t.Fatalf("Upload(%q): %v", name, err)
+		}
+	}
+
+	names, err := b.List(ctx, "a/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("List(%q): got %v, want 2 names", "a/", names)
+	}
+}
+
+func TestBackendPathEscape(t *testing.T) {
+	b, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, object := range []string{
+		"../escape.txt",
+		"../sibling-evil/secret.txt",
+		"a/../../escape.txt",
+	} {
+		if err := b.Upload(ctx, bytes.NewReader([]byte("x")), object); err == nil {
+			t.Errorf("Upload(%q): expected escape error, got nil", object)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package gcs is the Google Cloud Storage bucketfile.Backend.
+package gcs
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	"github.com/xyproto/bucketfile/backend"
+)
+
+// Backend is a bucketfile.Backend backed by a GCS bucket.
+type Backend struct {
+	client *storage.Client
+	bucket string
+}
+
+// New returns a Backend for the given GCS bucket, using application
+// default credentials.
+func New(ctx context.Context, bucket string) (*Backend, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage.NewClient: %v", err)
+	}
+	return &Backend{client: client, bucket: bucket}, nil
+}
+
+// Upload reads r and stores it as object.
+func (b *Backend) Upload(ctx context.Context, r io.Reader, object string) error {
+	wc := b.client.Bucket(b.bucket).Object(object).NewWriter(ctx)
+	if _, err := io.Copy(wc, r); err != nil {
+		return fmt.Errorf("io.Copy: %v", err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("Writer.Close: %v", err)
+	}
+	return nil
+}
+
+// Get returns a reader over object's data. Callers must Close it.
+func (b *Backend) Get(ctx context.Context, object string) (io.ReadCloser, error) {
+	rc, err := b.client.Bucket(b.bucket).Object(object).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Object(%q).NewReader: %v", object, err)
+	}
+	return rc, nil
+}
+
+// List returns the names of every object whose name starts with prefix.
+func (b *Backend) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+
+	it := b.client.Bucket(b.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return names, fmt.Errorf("Bucket(%q).Objects: %v", b.bucket, err)
+		}
+		names = append(names, attrs.Name)
+	}
+
+	return names, nil
+}
+
+// Delete removes object.
+func (b *Backend) Delete(ctx context.Context, object string) error {
+	if err := b.client.Bucket(b.bucket).Object(object).Delete(ctx); err != nil {
+		return fmt.Errorf("Object(%q).Delete: %v", object, err)
+	}
+	return nil
+}
+
+// Stat returns metadata about object without reading its data.
+func (b *Backend) Stat(ctx context.Context, object string) (backend.ObjectInfo, error) {
+	attrs, err := b.client.Bucket(b.bucket).Object(object).Attrs(ctx)
+	if err != nil {
+		return backend.ObjectInfo{}, fmt.Errorf("Object(%q).Attrs: %v", object, err)
+	}
+	return backend.ObjectInfo{Name: attrs.Name, Size: attrs.Size, ModTime: attrs.Updated}, nil
+}
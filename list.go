@@ -0,0 +1,112 @@
+package bucketfile
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// ListOptions configures Client.List and Client.ListAll.
+type ListOptions struct {
+	// Prefix restricts results to objects whose name starts with Prefix.
+	Prefix string
+	// Delimiter, typically "/", groups the portion of each name between
+	// Prefix and the next Delimiter into Prefixes instead of listing
+	// those objects individually, giving pseudo-folder listings.
+	Delimiter string
+	// PageToken resumes a previous List call; leave empty to start from
+	// the first page.
+	PageToken string
+	// PageSize caps how many objects a single List call returns. Zero
+	// or less uses 1000, the largest page GCS returns.
+	PageSize int
+	// IncludeAttrs, when true, makes List and ListAll populate each
+	// result's full *storage.ObjectAttrs (size, updated, contentType,
+	// md5) instead of just Name.
+	IncludeAttrs bool
+}
+
+// ListPage is a single page of results from Client.List.
+type ListPage struct {
+	// Objects holds one entry per object in the page. If
+	// ListOptions.IncludeAttrs was false, only Name is populated on each.
+	Objects []*storage.ObjectAttrs
+	// Prefixes holds the pseudo-folders found via ListOptions.Delimiter.
+	Prefixes []string
+	// NextPageToken, if non-empty, can be set as the next call's
+	// ListOptions.PageToken to continue listing.
+	NextPageToken string
+}
+
+// List lists a single page of objects in bucket matching opts, bound by
+// ctx. Use ListAll to stream every object without managing pages by hand.
+func (c *Client) List(ctx context.Context, bucket string, opts ListOptions) (*ListPage, error) {
+	it := c.sc.Bucket(bucket).Objects(ctx, &storage.Query{
+		Prefix:    opts.Prefix,
+		Delimiter: opts.Delimiter,
+	})
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		// iterator.NewPager rejects a non-positive page size.
+		pageSize = 1000
+	}
+	pager := iterator.NewPager(it, pageSize, opts.PageToken)
+	var attrs []*storage.ObjectAttrs
+	nextToken, err := pager.NextPage(&attrs)
+	if err != nil {
+		return nil, fmt.Errorf("Bucket(%q).Objects: %v", bucket, err)
+	}
+
+	page := &ListPage{NextPageToken: nextToken}
+	for _, a := range attrs {
+		if a.Prefix != "" {
+			page.Prefixes = append(page.Prefixes, a.Prefix)
+			continue
+		}
+		if !opts.IncludeAttrs {
+			a = &storage.ObjectAttrs{Name: a.Name}
+		}
+		page.Objects = append(page.Objects, a)
+	}
+
+	return page, nil
+}
+
+// ListAll lazily lists every object in bucket matching opts, fetching
+// further pages from the server only as the caller ranges over the
+// sequence. This scales to buckets with millions of objects, unlike
+// List's single page or the old List(bucket) that accumulated every name
+// into a slice up front. ListAll does not yield pseudo-folder prefixes;
+// use List for those.
+func (c *Client) ListAll(ctx context.Context, bucket string, opts ListOptions) iter.Seq2[*storage.ObjectAttrs, error] {
+	return func(yield func(*storage.ObjectAttrs, error) bool) {
+		it := c.sc.Bucket(bucket).Objects(ctx, &storage.Query{
+			Prefix:    opts.Prefix,
+			Delimiter: opts.Delimiter,
+		})
+
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				return
+			}
+			if err != nil {
+				yield(nil, fmt.Errorf("Bucket(%q).Objects: %v", bucket, err))
+				return
+			}
+			if attrs.Prefix != "" {
+				continue
+			}
+			if !opts.IncludeAttrs {
+				attrs = &storage.ObjectAttrs{Name: attrs.Name}
+			}
+			if !yield(attrs, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package s3 is the Amazon S3 bucketfile.Backend.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/xyproto/bucketfile/backend"
+)
+
+// Backend is a bucketfile.Backend backed by an S3 bucket.
+type Backend struct {
+	client *s3.Client
+	bucket string
+}
+
+// New returns a Backend for the given S3 bucket, using the default AWS
+// credential chain (environment, shared config, instance role, etc).
+func New(ctx context.Context, bucket string) (*Backend, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadDefaultConfig: %v", err)
+	}
+	return &Backend{client: s3.NewFromConfig(cfg), bucket: bucket}, nil
+}
+
+// Upload reads r and stores it as object.
+func (b *Backend) Upload(ctx context.Context, r io.Reader, object string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("io.ReadAll: %v", err)
+	}
+	_, err = b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(object),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("PutObject(%q): %v", object, err)
+	}
+	return nil
+}
+
+// Get returns a reader over object's data. Callers must Close it.
+func (b *Backend) Get(ctx context.Context, object string) (io.ReadCloser, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetObject(%q): %v", object, err)
+	}
+	return out.Body, nil
+}
+
+// List returns the names of every object whose name starts with prefix.
+func (b *Backend) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return names, fmt.Errorf("ListObjectsV2(%q): %v", b.bucket, err)
+		}
+		for _, obj := range page.Contents {
+			names = append(names, aws.ToString(obj.Key))
+		}
+	}
+
+	return names, nil
+}
+
+// Delete removes object.
+func (b *Backend) Delete(ctx context.Context, object string) error {
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return fmt.Errorf("DeleteObject(%q): %v", object, err)
+	}
+	return nil
+}
+
+// Stat returns metadata about object without reading its data.
+func (b *Backend) Stat(ctx context.Context, object string) (backend.ObjectInfo, error) {
+	out, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return backend.ObjectInfo{}, fmt.Errorf("HeadObject(%q): %v", object, err)
+	}
+	info := backend.ObjectInfo{Name: object, Size: aws.ToInt64(out.ContentLength)}
+	if out.LastModified != nil {
+		info.ModTime = *out.LastModified
+	}
+	return info, nil
+}
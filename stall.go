@@ -0,0 +1,112 @@
+package bucketfile
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// stallWindowSize is how many first-byte latency samples are kept per
+	// bucket when computing the dynamic stall timeout.
+	stallWindowSize = 1000
+
+	// defaultStallPercentile is the percentile of the rolling latency
+	// window used as the stall timeout.
+	defaultStallPercentile = 0.99
+
+	minStallTimeout = 500 * time.Millisecond
+	maxStallTimeout = 20 * time.Second
+
+	// maxStallRetries is how many times a stalled GET is reissued with a
+	// fresh reader before falling back to the caller's context deadline.
+	maxStallRetries = 3
+)
+
+// StallTimeoutEnabled is the package-level default for whether GetReader
+// (and Get) proactively cancel and retry a GET when the server hasn't
+// returned the first byte within a dynamically computed stall timeout.
+// It can be overridden per call with the ReadWithStallTimeout option.
+var StallTimeoutEnabled = false
+
+// stallWindow is a lock-protected rolling window of first-byte latencies
+// for a single bucket, used to derive that bucket's stall timeout.
+type stallWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration // ring buffer, insertion order
+	next    int
+}
+
+// add records a latency sample. Samples from stalled (timed-out) requests
+// are recorded as bounded, at maxStallTimeout, so a single long tail can't
+// poison the window and push future timeouts up indefinitely.
+func (w *stallWindow) add(d time.Duration, bounded bool) {
+	if bounded && d > maxStallTimeout {
+		d = maxStallTimeout
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) < stallWindowSize {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % stallWindowSize
+}
+
+// percentile returns the p-th percentile (0..1) of the window, and false
+// if the window has no samples yet.
+func (w *stallWindow) percentile(p float64) (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) == 0 {
+		return 0, false
+	}
+
+	sorted := append([]time.Duration(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx], true
+}
+
+var (
+	stallWindowsMu sync.Mutex
+	stallWindows   = map[string]*stallWindow{}
+)
+
+// stallWindowFor returns the shared stallWindow for bucket, creating it on
+// first use.
+func stallWindowFor(bucket string) *stallWindow {
+	stallWindowsMu.Lock()
+	defer stallWindowsMu.Unlock()
+
+	w, ok := stallWindows[bucket]
+	if !ok {
+		w = &stallWindow{}
+		stallWindows[bucket] = w
+	}
+	return w
+}
+
+// stallTimeoutFor returns the current stall timeout for bucket: the
+// defaultStallPercentile of its rolling first-byte latency window,
+// clamped to [minStallTimeout, maxStallTimeout]. While the window has no
+// samples yet, it returns maxStallTimeout so early requests aren't
+// retried prematurely.
+func stallTimeoutFor(bucket string) time.Duration {
+	d, ok := stallWindowFor(bucket).percentile(defaultStallPercentile)
+	if !ok {
+		return maxStallTimeout
+	}
+	if d < minStallTimeout {
+		return minStallTimeout
+	}
+	if d > maxStallTimeout {
+		return maxStallTimeout
+	}
+	return d
+}
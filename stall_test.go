@@ -0,0 +1,112 @@
+package bucketfile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStallWindowWraparound(t *testing.T) {
+	w := &stallWindow{}
+
+	for i := 0; i < stallWindowSize; i++ {
+		w.add(time.Second, false)
+	}
+	if got := len(w.samples); got != stallWindowSize {
+		t.Fatalf("len(samples) = %d, want %d", got, stallWindowSize)
+	}
+
+	// One more sample should overwrite the oldest slot (index 0) rather
+	// than growing the buffer.
+	w.add(2*time.Second, false)
+	if got := len(w.samples); got != stallWindowSize {
+		t.Fatalf("len(samples) after wraparound = %d, want %d", got, stallWindowSize)
+	}
+	if w.samples[0] != 2*time.Second {
+		t.Fatalf("samples[0] = %v, want %v (overwritten oldest slot)", w.samples[0], 2*time.Second)
+	}
+	if w.next != 1 {
+		t.Fatalf("next = %d, want 1", w.next)
+	}
+
+	p, ok := w.percentile(0)
+	if !ok {
+		t.Fatalf("percentile: ok = false, want true")
+	}
+	if p != time.Second {
+		t.Fatalf("percentile(0) = %v, want %v", p, time.Second)
+	}
+}
+
+func TestStallWindowPercentile(t *testing.T) {
+	w := &stallWindow{}
+	for i := 1; i <= 100; i++ {
+		w.add(time.Duration(i)*time.Millisecond, false)
+	}
+
+	if p, _ := w.percentile(0); p != 1*time.Millisecond {
+		t.Errorf("percentile(0) = %v, want 1ms", p)
+	}
+	if p, _ := w.percentile(1); p != 100*time.Millisecond {
+		t.Errorf("percentile(1) = %v, want 100ms", p)
+	}
+	if p, _ := w.percentile(0.99); p != 99*time.Millisecond {
+		t.Errorf("percentile(0.99) = %v, want 99ms", p)
+	}
+}
+
+func TestStallWindowPercentileEmpty(t *testing.T) {
+	w := &stallWindow{}
+	if _, ok := w.percentile(0.99); ok {
+		t.Fatalf("percentile on empty window: ok = true, want false")
+	}
+}
+
+func TestStallWindowAddBoundedCapsAtMax(t *testing.T) {
+	w := &stallWindow{}
+	w.add(maxStallTimeout+time.Minute, true)
+
+	got, ok := w.percentile(1)
+	if !ok {
+		t.Fatalf("percentile: ok = false, want true")
+	}
+	if got != maxStallTimeout {
+		t.Fatalf("bounded sample = %v, want capped at %v", got, maxStallTimeout)
+	}
+}
+
+func TestStallWindowAddUnboundedNotCapped(t *testing.T) {
+	w := &stallWindow{}
+	large := maxStallTimeout + time.Minute
+	w.add(large, false)
+
+	got, ok := w.percentile(1)
+	if !ok {
+		t.Fatalf("percentile: ok = false, want true")
+	}
+	if got != large {
+		t.Fatalf("unbounded sample = %v, want uncapped %v", got, large)
+	}
+}
+
+func TestStallTimeoutForClamps(t *testing.T) {
+	const bucket = "test-clamp-bucket"
+
+	// No samples yet: stallTimeoutFor should return maxStallTimeout so
+	// early requests aren't retried prematurely.
+	if got := stallTimeoutFor(bucket); got != maxStallTimeout {
+		t.Fatalf("stallTimeoutFor with no samples = %v, want %v", got, maxStallTimeout)
+	}
+
+	w := stallWindowFor(bucket)
+	w.add(1*time.Millisecond, false)
+	if got := stallTimeoutFor(bucket); got != minStallTimeout {
+		t.Fatalf("stallTimeoutFor with tiny samples = %v, want clamped to %v", got, minStallTimeout)
+	}
+
+	bucketHigh := "test-clamp-bucket-high"
+	wHigh := stallWindowFor(bucketHigh)
+	wHigh.add(maxStallTimeout+time.Hour, false)
+	if got := stallTimeoutFor(bucketHigh); got != maxStallTimeout {
+		t.Fatalf("stallTimeoutFor with huge samples = %v, want clamped to %v", got, maxStallTimeout)
+	}
+}
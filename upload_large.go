@@ -0,0 +1,253 @@
+package bucketfile
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+// DefaultChunkSize is the size of each part uploaded by UploadLarge when
+// no ChunkSize option is given.
+const DefaultChunkSize = 32 * 1024 * 1024 // 32 MiB
+
+// minChunkBytesPerSec is the assumed worst-case upload throughput used to
+// derive a per-chunk context deadline, so that a slow connection doesn't
+// trip a hard, size-independent timeout.
+const minChunkBytesPerSec = 256 * 1024 // 256 KiB/s
+
+// maxComposeSources is the maximum number of source objects GCS accepts
+// in a single storage.Composer.Run call.
+const maxComposeSources = 32
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// UploadOption configures UploadLarge.
+type UploadOption func(*uploadConfig)
+
+type uploadConfig struct {
+	chunkSize   int64
+	concurrency int
+}
+
+// ChunkSize sets the size, in bytes, of each part UploadLarge uploads
+// before composing them into the final object. The default is
+// DefaultChunkSize.
+func ChunkSize(bytes int64) UploadOption {
+	return func(c *uploadConfig) {
+		c.chunkSize = bytes
+	}
+}
+
+// Concurrency sets how many parts UploadLarge uploads in parallel. The
+// default is GOMAXPROCS.
+func Concurrency(n int) UploadOption {
+	return func(c *uploadConfig) {
+		c.concurrency = n
+	}
+}
+
+// UploadLarge uploads r to bucket/object by splitting it into fixed-size
+// chunks, uploading each chunk in parallel as a temporary object named
+// "<object>.part-<n>", and then server-side composing the parts into the
+// final object via storage.Composer. The temporary parts are deleted once
+// the compose succeeds.
+//
+// Unlike Upload, UploadLarge has no fixed timeout: each chunk gets its own
+// context whose deadline is derived from the chunk size and the minimum
+// throughput minChunkBytesPerSec, so large files don't get cut off by a
+// hard 50s limit. That throughput floor is fixed; no UploadOption sets it.
+//
+// After composing, UploadLarge verifies that the composed object's CRC32C
+// matches the CRC32C of the input as read locally, and deletes the composed
+// object if they don't match. On any chunk failure, it makes a best-effort
+// attempt to delete all part objects that were already uploaded before
+// returning the error.
+func UploadLarge(ctx context.Context, r io.Reader, bucket, object string, opts ...UploadOption) (*storage.ObjectAttrs, error) {
+	cfg := uploadConfig{
+		chunkSize:   DefaultChunkSize,
+		concurrency: runtime.GOMAXPROCS(0),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.chunkSize <= 0 {
+		return nil, fmt.Errorf("UploadLarge: ChunkSize must be positive, got %d", cfg.chunkSize)
+	}
+	if cfg.concurrency <= 0 {
+		return nil, fmt.Errorf("UploadLarge: Concurrency must be positive, got %d", cfg.concurrency)
+	}
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage.NewClient: %v", err)
+	}
+	defer client.Close()
+
+	bkt := client.Bucket(bucket)
+
+	var (
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, cfg.concurrency)
+		mu       sync.Mutex
+		partErr  error
+		partObjs []string
+		checksum = crc32.New(crc32cTable)
+	)
+
+	for n := 0; ; n++ {
+		mu.Lock()
+		stop := partErr != nil
+		mu.Unlock()
+		if stop {
+			break
+		}
+
+		buf := make([]byte, cfg.chunkSize)
+		read, readErr := io.ReadFull(r, buf)
+		if read > 0 {
+			buf = buf[:read]
+			checksum.Write(buf)
+
+			partName := fmt.Sprintf("%s.part-%d", object, n)
+
+			mu.Lock()
+			partObjs = append(partObjs, partName)
+			mu.Unlock()
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(partName string, data []byte) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				timeout := time.Duration(float64(len(data))/minChunkBytesPerSec*float64(time.Second)) + 10*time.Second
+				partCtx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+
+				wc := bkt.Object(partName).NewWriter(partCtx)
+				if _, err := wc.Write(data); err != nil {
+					mu.Lock()
+					if partErr == nil {
+						partErr = fmt.Errorf("part %s: Writer.Write: %v", partName, err)
+					}
+					mu.Unlock()
+					return
+				}
+				if err := wc.Close(); err != nil {
+					mu.Lock()
+					if partErr == nil {
+						partErr = fmt.Errorf("part %s: Writer.Close: %v", partName, err)
+					}
+					mu.Unlock()
+				}
+			}(partName, buf)
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			wg.Wait()
+			deleteParts(ctx, bkt, partObjs)
+			return nil, fmt.Errorf("reading input: %v", readErr)
+		}
+	}
+
+	wg.Wait()
+
+	if partErr != nil {
+		deleteParts(ctx, bkt, partObjs)
+		return nil, partErr
+	}
+
+	if len(partObjs) == 0 {
+		return nil, fmt.Errorf("UploadLarge: empty input")
+	}
+
+	attrs, tempObjs, err := composeTree(ctx, bkt, object, partObjs)
+	if err != nil {
+		deleteParts(ctx, bkt, append(partObjs, tempObjs...))
+		return nil, err
+	}
+
+	if attrs.CRC32C != checksum.Sum32() {
+		bkt.Object(object).Delete(ctx)
+		deleteParts(ctx, bkt, append(partObjs, tempObjs...))
+		return nil, fmt.Errorf("UploadLarge: CRC32C mismatch: object has %d, input has %d", attrs.CRC32C, checksum.Sum32())
+	}
+
+	deleteParts(ctx, bkt, append(partObjs, tempObjs...))
+
+	return attrs, nil
+}
+
+// composeTree composes parts into finalObject, working around the GCS
+// limit of maxComposeSources objects per Composer.Run call: it composes
+// parts in batches of at most maxComposeSources into intermediate
+// objects, then repeats on those intermediates, until one final compose
+// into finalObject is possible. It returns the final object's attrs and
+// the names of every intermediate object created along the way, so the
+// caller can clean them up alongside the original parts.
+func composeTree(ctx context.Context, bkt *storage.BucketHandle, finalObject string, parts []string) (*storage.ObjectAttrs, []string, error) {
+	var tempObjs []string
+
+	for round := 0; len(parts) > maxComposeSources; round++ {
+		var next []string
+		for i := 0; i < len(parts); i += maxComposeSources {
+			end := i + maxComposeSources
+			if end > len(parts) {
+				end = len(parts)
+			}
+			batch := parts[i:end]
+
+			interName := fmt.Sprintf("%s.compose-%d-%d", finalObject, round, i/maxComposeSources)
+			if _, err := compose(ctx, bkt, interName, batch); err != nil {
+				return nil, tempObjs, err
+			}
+
+			tempObjs = append(tempObjs, interName)
+			next = append(next, interName)
+		}
+		parts = next
+	}
+
+	attrs, err := compose(ctx, bkt, finalObject, parts)
+	if err != nil {
+		return nil, tempObjs, err
+	}
+
+	return attrs, tempObjs, nil
+}
+
+// compose runs a single storage.Composer.Run call, composing srcNames
+// into destObject. Callers must ensure len(srcNames) <= maxComposeSources.
+func compose(ctx context.Context, bkt *storage.BucketHandle, destObject string, srcNames []string) (*storage.ObjectAttrs, error) {
+	srcs := make([]*storage.ObjectHandle, len(srcNames))
+	for i, name := range srcNames {
+		srcs[i] = bkt.Object(name)
+	}
+
+	composeCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	attrs, err := bkt.Object(destObject).ComposerFrom(srcs...).Run(composeCtx)
+	if err != nil {
+		return nil, fmt.Errorf("Composer.Run(%s): %v", destObject, err)
+	}
+	return attrs, nil
+}
+
+// deleteParts best-effort deletes the given part objects, ignoring errors
+// since it is only ever called during cleanup.
+func deleteParts(ctx context.Context, bkt *storage.BucketHandle, names []string) {
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		bkt.Object(name).Delete(ctx)
+		cancel()
+	}
+}